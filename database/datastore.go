@@ -0,0 +1,52 @@
+// Copyright 2017 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+// Session is a atomic database transaction.
+type Session interface {
+	// FindKeyValue returns the value stored under key, and whether it was
+	// found.
+	FindKeyValue(key string) (value string, ok bool, err error)
+
+	// InsertKeyValue stores value under key, overwriting any previous
+	// value.
+	InsertKeyValue(key, value string) error
+
+	// Commit commits the transaction.
+	Commit() error
+
+	// Rollback rolls back the transaction. It is always safe to call
+	// Rollback after Commit.
+	Rollback() error
+}
+
+// Datastore opens Sessions onto the persisted vulnerability/feature data.
+type Datastore interface {
+	Begin() (Session, error)
+}
+
+// FindKeyValueAndRollback opens a Session against ds, looks up key, and
+// rolls the Session back before returning. It exists so vulnsrc updaters,
+// which never write through their own Session, don't have to repeat the
+// open/lookup/rollback boilerplate.
+func FindKeyValueAndRollback(ds Datastore, key string) (value string, ok bool, err error) {
+	session, err := ds.Begin()
+	if err != nil {
+		return "", false, err
+	}
+	defer session.Rollback()
+
+	return session.FindKeyValue(key)
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+// FeatureType denotes whether a Feature was installed as a source package
+// or a binary package.
+type FeatureType string
+
+const (
+	SourcePackage FeatureType = "source"
+	BinaryPackage FeatureType = "binary"
+)
+
+// Namespace identifies the operating system/release (and, for amzn,
+// architecture) an AffectedFeature's versions are meaningful within, e.g.
+// "debian:11" or "amzn:2:aarch64".
+type Namespace struct {
+	Name          string
+	VersionFormat string
+}
+
+// MetadataMap carries vulnerability source-specific data (e.g. CVSS
+// vectors/scores, CWE ids) that doesn't fit Vulnerability's other fields.
+type MetadataMap map[string]interface{}
+
+// AffectedFeature describes a package, within a given Namespace, that a
+// Vulnerability affects.
+type AffectedFeature struct {
+	FeatureType     FeatureType
+	Namespace       Namespace
+	FeatureName     string
+	AffectedVersion string
+	FixedInVersion  string
+
+	// SupportSource records which vendor is providing the fix tracked by
+	// this AffectedFeature, e.g. "debian-security" | "debian-lts" |
+	// "freexian-elts" for Debian's updater. Empty when a source doesn't
+	// distinguish.
+	SupportSource string
+
+	// PURLQualifier carries the Package URL "distro" qualifier (and, where
+	// applicable, "arch") matching this AffectedFeature's Namespace, as
+	// produced by ext/vulnsrc/purl.Qualifiers (e.g. "arch=aarch64&distro=amzn-2"). Empty if
+	// the source updater has no mapping for its namespace.
+	PURLQualifier string
+}
+
+// Vulnerability is a vulnerability as reported by a vulnerability source,
+// independent of any package it affects.
+type Vulnerability struct {
+	Name        string
+	Namespace   Namespace
+	Description string
+	Link        string
+	Severity    Severity
+	Metadata    MetadataMap
+}
+
+// VulnerabilityWithAffected is a Vulnerability together with every
+// AffectedFeature a vulnsrc updater found for it in a single fetch.
+type VulnerabilityWithAffected struct {
+	Vulnerability
+	Affected []AffectedFeature
+}
+
+// DebianReleasesMapping maps a Debian release codename, as used by Debian's
+// and Freexian's tracker jsons, to the release number Clair namespaces
+// packages under (e.g. "bullseye" -> "11", so features are filed under
+// "debian:11"). Codenames whose release has gone end-of-life under Debian's
+// own security-tracker are kept here too, since Freexian's LTS/ELTS trackers
+// still report fixes for them.
+var DebianReleasesMapping = map[string]string{
+	"squeeze":  "6",
+	"wheezy":   "7",
+	"jessie":   "8",
+	"stretch":  "9",
+	"buster":   "10",
+	"bullseye": "11",
+	"bookworm": "12",
+	"sid":      "unstable",
+}
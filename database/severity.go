@@ -0,0 +1,48 @@
+// Copyright 2017 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+// Severity defines a vulnerability severity, ordered from least to most
+// severe.
+type Severity string
+
+// Severities, from least to most severe. Order matters: it's what backs
+// Severity.Compare.
+const (
+	UnknownSeverity    Severity = "Unknown"
+	NegligibleSeverity Severity = "Negligible"
+	LowSeverity        Severity = "Low"
+	MediumSeverity     Severity = "Medium"
+	HighSeverity       Severity = "High"
+	CriticalSeverity   Severity = "Critical"
+	Defcon1Severity    Severity = "Defcon1"
+)
+
+// severityOrder ranks each Severity for Compare.
+var severityOrder = map[Severity]int{
+	UnknownSeverity:    0,
+	NegligibleSeverity: 1,
+	LowSeverity:        2,
+	MediumSeverity:     3,
+	HighSeverity:       4,
+	CriticalSeverity:   5,
+	Defcon1Severity:    6,
+}
+
+// Compare returns a negative number, zero, or a positive number depending on
+// whether p is less severe than, as severe as, or more severe than p2.
+func (p Severity) Compare(p2 Severity) int {
+	return severityOrder[p] - severityOrder[p2]
+}
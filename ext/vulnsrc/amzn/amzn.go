@@ -24,6 +24,7 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -32,15 +33,24 @@ import (
 	"github.com/coreos/clair/ext/versionfmt"
 	"github.com/coreos/clair/ext/versionfmt/rpm"
 	"github.com/coreos/clair/ext/vulnsrc"
+	"github.com/coreos/clair/ext/vulnsrc/purl"
 	"github.com/coreos/clair/pkg/commonerr"
 	"github.com/coreos/clair/pkg/httputil"
 )
 
 var (
-	// This will be overwritten by os.GetEnv("VULNSRC_AMZN1_MIRROR") if present
-	amazonLinux1MirrorListURI = "http://repo.us-west-2.amazonaws.com/2018.03/updates/x86_64/mirror.list"
-	// This will be overwritten by os.GetEnv("VULNSRC_AMZN2_MIRROR") if present
-	amazonLinux2MirrorListURI = "https://cdn.amazonlinux.com/2/core/latest/x86_64/mirror.list"
+	// This will be overwritten by os.GetEnv("VULNSRC_AMZN1_MIRROR") if present.
+	// %s is substituted with the architecture (see defaultArches).
+	amazonLinux1MirrorListURI = "http://repo.us-west-2.amazonaws.com/2018.03/updates/%s/mirror.list"
+	// This will be overwritten by os.GetEnv("VULNSRC_AMZN2_MIRROR") if present.
+	// %s is substituted with the architecture (see defaultArches).
+	amazonLinux2MirrorListURI = "https://cdn.amazonlinux.com/2/core/latest/%s/mirror.list"
+	// This will be overwritten by os.GetEnv("VULNSRC_AMZN2022_MIRROR") if present.
+	// %s is substituted with the architecture (see defaultArches).
+	amazonLinux2022MirrorListURI = "https://cdn.amazonlinux.com/al2022/core/mirrors/latest/%s/mirror.list"
+	// This will be overwritten by os.GetEnv("VULNSRC_AMZN2023_MIRROR") if present.
+	// %s is substituted with the architecture (see defaultArches).
+	amazonLinux2023MirrorListURI = "https://cdn.amazonlinux.com/al2023/core/mirrors/latest/%s/mirror.list"
 )
 
 const (
@@ -53,14 +63,44 @@ const (
 	amazonLinux2Name        = "Amazon Linux 2"
 	amazonLinux2Namespace   = "amzn:2"
 	amazonLinux2LinkFormat  = "https://alas.aws.amazon.com/AL2/%s.html"
+
+	amazonLinux2022UpdaterFlag = "amazonLinux2022Updater"
+	amazonLinux2022Name        = "Amazon Linux 2022"
+	amazonLinux2022Namespace   = "amzn:2022"
+	amazonLinux2022LinkFormat  = "https://alas.aws.amazon.com/AL2022/%s.html"
+
+	amazonLinux2023UpdaterFlag = "amazonLinux2023Updater"
+	amazonLinux2023Name        = "Amazon Linux 2023"
+	amazonLinux2023Namespace   = "amzn:2023"
+	amazonLinux2023LinkFormat  = "https://alas.aws.amazon.com/AL2023/%s.html"
+
+	// primaryArch is the architecture whose namespace is left unsuffixed, to
+	// stay compatible with data already stored under e.g. "amzn:2".
+	primaryArch = "x86_64"
 )
 
+// alasLinkRewrites maps an updater's Name to the regexp used to rewrite its
+// raw ALAS id (e.g. "ALAS2023-2023-123") into the id used in its advisory
+// URL (e.g. "ALAS-2023-123"), so new Amazon Linux majors only need an entry here instead of another
+// alasToLink branch.
+var alasLinkRewrites = map[string]*regexp.Regexp{
+	amazonLinux2Name:    regexp.MustCompile(`^ALAS2-(.+)$`),
+	amazonLinux2022Name: regexp.MustCompile(`^ALAS2022-(.+)$`),
+	amazonLinux2023Name: regexp.MustCompile(`^ALAS2023-(.+)$`),
+}
+
+// defaultArches is the set of architectures each updater fetches ALAS data
+// for unless overridden by a VULNSRC_AMZN{1,2,2022,2023}_ARCHES environment
+// variable.
+var defaultArches = []string{"x86_64", "aarch64"}
+
 type updater struct {
-	UpdaterFlag   string
-	MirrorListURI string
-	Name          string
-	Namespace     string
-	LinkFormat    string
+	UpdaterFlag       string
+	MirrorListURITmpl string
+	Name              string
+	Namespace         string
+	LinkFormat        string
+	Arches            []string
 }
 
 func init() {
@@ -71,25 +111,167 @@ func init() {
 	if os.Getenv("VULNSRC_AMZN2_MIRROR") != "" {
 		amazonLinux2MirrorListURI = os.Getenv("VULNSRC_AMZN2_MIRROR")
 	}
+	if os.Getenv("VULNSRC_AMZN2022_MIRROR") != "" {
+		amazonLinux2022MirrorListURI = os.Getenv("VULNSRC_AMZN2022_MIRROR")
+	}
+	if os.Getenv("VULNSRC_AMZN2023_MIRROR") != "" {
+		amazonLinux2023MirrorListURI = os.Getenv("VULNSRC_AMZN2023_MIRROR")
+	}
+
 	// Register updater for Amazon Linux 2018.03.
 	amazonLinux1Updater := updater{
-		UpdaterFlag:   amazonLinux1UpdaterFlag,
-		MirrorListURI: amazonLinux1MirrorListURI,
-		Name:          amazonLinux1Name,
-		Namespace:     amazonLinux1Namespace,
-		LinkFormat:    amazonLinux1LinkFormat,
+		UpdaterFlag:       amazonLinux1UpdaterFlag,
+		MirrorListURITmpl: amazonLinux1MirrorListURI,
+		Name:              amazonLinux1Name,
+		Namespace:         amazonLinux1Namespace,
+		LinkFormat:        amazonLinux1LinkFormat,
+		Arches:            arches("VULNSRC_AMZN1_ARCHES"),
 	}
 	vulnsrc.RegisterUpdater("amzn1", &amazonLinux1Updater)
 
 	// Register updater for Amazon Linux 2.
 	amazonLinux2Updater := updater{
-		UpdaterFlag:   amazonLinux2UpdaterFlag,
-		MirrorListURI: amazonLinux2MirrorListURI,
-		Name:          amazonLinux2Name,
-		Namespace:     amazonLinux2Namespace,
-		LinkFormat:    amazonLinux2LinkFormat,
+		UpdaterFlag:       amazonLinux2UpdaterFlag,
+		MirrorListURITmpl: amazonLinux2MirrorListURI,
+		Name:              amazonLinux2Name,
+		Namespace:         amazonLinux2Namespace,
+		LinkFormat:        amazonLinux2LinkFormat,
+		Arches:            arches("VULNSRC_AMZN2_ARCHES"),
 	}
 	vulnsrc.RegisterUpdater("amzn2", &amazonLinux2Updater)
+
+	// Register updater for Amazon Linux 2022.
+	amazonLinux2022Updater := updater{
+		UpdaterFlag:       amazonLinux2022UpdaterFlag,
+		MirrorListURITmpl: amazonLinux2022MirrorListURI,
+		Name:              amazonLinux2022Name,
+		Namespace:         amazonLinux2022Namespace,
+		LinkFormat:        amazonLinux2022LinkFormat,
+		Arches:            arches("VULNSRC_AMZN2022_ARCHES"),
+	}
+	vulnsrc.RegisterUpdater("amzn2022", &amazonLinux2022Updater)
+
+	// Register updater for Amazon Linux 2023.
+	amazonLinux2023Updater := updater{
+		UpdaterFlag:       amazonLinux2023UpdaterFlag,
+		MirrorListURITmpl: amazonLinux2023MirrorListURI,
+		Name:              amazonLinux2023Name,
+		Namespace:         amazonLinux2023Namespace,
+		LinkFormat:        amazonLinux2023LinkFormat,
+		Arches:            arches("VULNSRC_AMZN2023_ARCHES"),
+	}
+	vulnsrc.RegisterUpdater("amzn2023", &amazonLinux2023Updater)
+}
+
+// arches returns the architectures an updater should fetch ALAS data for,
+// taking the comma-separated list in the given environment variable into
+// account when it is set.
+func arches(envVar string) []string {
+	raw := os.Getenv(envVar)
+	if raw == "" {
+		return defaultArches
+	}
+
+	var arches []string
+	for _, a := range strings.Split(raw, ",") {
+		a = strings.TrimSpace(a)
+		if a != "" {
+			arches = append(arches, a)
+		}
+	}
+	if len(arches) == 0 {
+		return defaultArches
+	}
+	return arches
+}
+
+// sourceMode reports which feed the Amazon Linux 2 updater should use,
+// controlled by VULNSRC_AMZN2_SOURCE. It defaults to sourceUpdateInfo.
+func sourceMode() string {
+	switch os.Getenv("VULNSRC_AMZN2_SOURCE") {
+	case sourceOval:
+		return sourceOval
+	default:
+		return sourceUpdateInfo
+	}
+}
+
+// encodeFlag packs each architecture's "since" timestamp together with the
+// HTTP validators of its last successful fetch, sorted by architecture, e.g.
+// "|aarch64=\"def\",2019-05-01 08:00,Mon...;x86_64=\"abc\",2019-06-03 12:00,Mon...".
+// Tracking Since per architecture (rather than one timestamp shared across
+// all of them) means an architecture added to VULNSRC_AMZN*_ARCHES on a
+// running deployment starts from its own zero value instead of being
+// filtered against every other architecture's already-advanced timestamp.
+// LastModified is ordered last, not second, because it's itself a comma-
+// bearing RFC 1123 date (e.g. "Mon, 02 Jan 2006 15:04:05 GMT") - putting it
+// anywhere but last would make decodeFlag's SplitN misparse it.
+func encodeFlag(validators map[string]httpValidators) string {
+	arches := make([]string, 0, len(validators))
+	for arch := range validators {
+		arches = append(arches, arch)
+	}
+	sort.Strings(arches)
+
+	entries := make([]string, 0, len(arches))
+	for _, arch := range arches {
+		v := validators[arch]
+		entries = append(entries, arch+"="+v.ETag+","+v.Since+","+v.LastModified)
+	}
+
+	// The leading "|" keeps the flag's shape compatible with decodeFlag's
+	// "legacyTimestamp|entries" split even though there's no longer a
+	// top-level timestamp to put before it.
+	return "|" + strings.Join(entries, ";")
+}
+
+// decodeFlag reverses encodeFlag. legacyTimestamp carries the single
+// "since" value written by flags predating per-architecture tracking - the
+// caller should fall back to it for any architecture decodeFlag's
+// validators map has no entry for.
+func decodeFlag(flag string) (validators map[string]httpValidators, legacyTimestamp string) {
+	validators = make(map[string]httpValidators)
+
+	parts := strings.SplitN(flag, "|", 2)
+	if len(parts) < 2 {
+		// Oldest format: a bare "since" timestamp, no validators at all.
+		return validators, parts[0]
+	}
+	legacyTimestamp = parts[0]
+
+	for _, entry := range strings.Split(parts[1], ";") {
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		fields := strings.SplitN(kv[1], ",", 3)
+		var v httpValidators
+		if len(fields) > 0 {
+			v.ETag = fields[0]
+		}
+		if len(fields) > 1 {
+			// Current format already carries this architecture's own
+			// Since; legacyTimestamp is only a fallback for arches below.
+			v.Since = fields[1]
+		}
+		if len(fields) > 2 {
+			v.LastModified = fields[2]
+		}
+		validators[kv[0]] = v
+	}
+
+	return
+}
+
+// namespaceForArch returns the namespace ALAS data fetched for the given
+// architecture should be filed under. The primary architecture keeps the
+// updater's base namespace (e.g. "amzn:2") for backwards compatibility;
+// other architectures get an arch-qualified namespace (e.g. "amzn:2:aarch64").
+func (u *updater) namespaceForArch(arch string) string {
+	if arch == primaryArch {
+		return u.Namespace
+	}
+	return u.Namespace + ":" + arch
 }
 
 func (u *updater) Update(datastore database.Datastore) (vulnsrc.UpdateResponse, error) {
@@ -104,74 +286,126 @@ func (u *updater) Update(datastore database.Datastore) (vulnsrc.UpdateResponse,
 	if !found {
 		flagValue = ""
 	}
+	prevValidators, legacyTimestamp := decodeFlag(flagValue)
+
+	// Amazon Linux 2 can alternatively be sourced from Amazon's OVAL feed,
+	// which carries CVSSv3/CWE metadata that updateinfo.xml.gz lacks. OVAL
+	// doesn't expose the same "since last update" timestamp updateinfo.xml.gz
+	// does, so this path always re-submits the full vulnerability set; the
+	// datastore dedupes on (name, affected) before persisting.
+	if u.Name == amazonLinux2Name && sourceMode() == sourceOval {
+		vulnerabilities, err := u.updateFromOval()
+		if err != nil {
+			return vulnsrc.UpdateResponse{}, err
+		}
+		return vulnsrc.UpdateResponse{Vulnerabilities: vulnerabilities}, nil
+	}
 
-	var timestamp string
+	// Get the ALASs which were issued/updated since the previous update, for
+	// every architecture this updater tracks. Vulnerabilities are merged by
+	// ALAS id/arch so that a package only shipped for one architecture still
+	// gets its own AffectedFeature. A single architecture failing to fetch
+	// (e.g. amzn1 has no aarch64 mirror at all in some regions) only drops
+	// that architecture for this run, carrying its previous validator/since
+	// forward unchanged so the next run retries it from the same point,
+	// instead of discarding every other architecture's successful fetch too.
+	vulnerabilities := make(map[string]*database.VulnerabilityWithAffected)
+	validators := make(map[string]httpValidators, len(u.Arches))
+	anyUpdated := false
+	for _, arch := range u.Arches {
+		prevValidator := prevValidators[arch]
+		if prevValidator.Since == "" {
+			prevValidator.Since = legacyTimestamp
+		}
 
-	// Get the ALASs from updateinfo.xml.gz from the repos.
-	updateInfo, err := u.getUpdateInfo()
-	if err != nil {
-		return vulnsrc.UpdateResponse{}, err
-	}
+		updateInfo, validator, notModified, err := u.getUpdateInfo(arch, prevValidator)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{"package": u.Name, "arch": arch}).Error("could not fetch updateinfo.xml.gz for architecture, skipping it this run")
+			validators[arch] = prevValidator
+			continue
+		}
+		validator.Since = prevValidator.Since
+
+		if notModified {
+			log.WithFields(log.Fields{"package": u.Name, "arch": arch}).Debug("updateinfo.xml.gz not modified, skip")
+			validators[arch] = validator
+			continue
+		}
 
-	// Get the ALASs which were issued/updated since the previous update.
-	var alasList []ALAS
-	for _, alas := range updateInfo.ALASList {
-		if compareTimestamp(alas.Updated.Date, flagValue) > 0 {
-			alasList = append(alasList, alas)
+		var alasList []ALAS
+		for _, alas := range updateInfo.ALASList {
+			if compareTimestamp(alas.Updated.Date, prevValidator.Since) > 0 {
+				alasList = append(alasList, alas)
+				anyUpdated = true
 
-			if compareTimestamp(alas.Updated.Date, timestamp) > 0 {
-				timestamp = alas.Updated.Date
+				if compareTimestamp(alas.Updated.Date, validator.Since) > 0 {
+					validator.Since = alas.Updated.Date
+				}
 			}
 		}
-	}
+		validators[arch] = validator
 
-	// Get the vulnerabilities.
-	vulnerabilities := u.alasListToVulnerabilities(alasList)
+		u.mergeAlasListToVulnerabilities(alasList, arch, vulnerabilities)
+	}
 
 	response := vulnsrc.UpdateResponse{
-		Vulnerabilities: vulnerabilities,
+		Vulnerabilities: flattenVulnerabilities(vulnerabilities),
 	}
 
-	// Set the flag value.
-	if timestamp != "" {
-		response.FlagName = u.UpdaterFlag
-		response.FlagValue = timestamp
-	} else {
+	response.FlagName = u.UpdaterFlag
+	response.FlagValue = encodeFlag(validators)
+	if !anyUpdated {
 		log.WithField("package", u.Name).Debug("no update")
 	}
 
-	return response, err
+	return response, nil
 }
 
 func (u *updater) Clean() {
 
 }
 
-func (u *updater) getUpdateInfo() (UpdateInfo, error) {
+// httpValidators are the conditional-GET validators returned alongside a
+// successful updateinfo.xml.gz fetch for one architecture, together with
+// that architecture's own "since" timestamp.
+type httpValidators struct {
+	ETag         string
+	LastModified string
+	// Since is the Updated.Date of the newest ALAS seen for this
+	// architecture as of the last successful fetch.
+	Since string
+}
+
+func (u *updater) getUpdateInfo(arch string, prev httpValidators) (UpdateInfo, httpValidators, bool, error) {
 	// Get the URI of updateinfo.xml.gz.
-	updateInfoURI, err := u.getUpdateInfoURI()
+	updateInfoURI, err := u.getUpdateInfoURI(arch)
 	if err != nil {
-		return UpdateInfo{}, err
+		return UpdateInfo{}, httpValidators{}, false, err
 	}
 
-	// Download updateinfo.xml.gz.
-	updateInfoResponse, err := httputil.GetWithUserAgent(updateInfoURI)
+	// Download updateinfo.xml.gz, skipping the body entirely if it hasn't
+	// changed since the last time we fetched it for this architecture.
+	updateInfoResponse, err := httputil.GetConditional(updateInfoURI, prev.ETag, prev.LastModified)
 	if err != nil {
 		log.WithError(err).Error("could not download updateinfo.xml.gz")
-		return UpdateInfo{}, commonerr.ErrCouldNotDownload
+		return UpdateInfo{}, httpValidators{}, false, commonerr.ErrCouldNotDownload
 	}
 	defer updateInfoResponse.Body.Close()
 
+	if httputil.NotModified(updateInfoResponse) {
+		return UpdateInfo{}, prev, true, nil
+	}
+
 	if !httputil.Status2xx(updateInfoResponse) {
 		log.WithField("StatusCode", updateInfoResponse.StatusCode).Error("could not download updateinfo.xml.gz")
-		return UpdateInfo{}, commonerr.ErrCouldNotDownload
+		return UpdateInfo{}, httpValidators{}, false, commonerr.ErrCouldNotDownload
 	}
 
 	// Decompress updateinfo.xml.gz.
 	updateInfoXml, err := gzip.NewReader(updateInfoResponse.Body)
 	if err != nil {
 		log.WithError(err).Error("could not decompress updateinfo.xml.gz")
-		return UpdateInfo{}, commonerr.ErrCouldNotParse
+		return UpdateInfo{}, httpValidators{}, false, commonerr.ErrCouldNotParse
 	}
 	defer updateInfoXml.Close()
 
@@ -179,15 +413,19 @@ func (u *updater) getUpdateInfo() (UpdateInfo, error) {
 	updateInfo, err := decodeUpdateInfo(updateInfoXml)
 	if err != nil {
 		log.WithError(err).Error("could not decode updateinfo.xml")
-		return UpdateInfo{}, commonerr.ErrCouldNotParse
+		return UpdateInfo{}, httpValidators{}, false, commonerr.ErrCouldNotParse
 	}
 
-	return updateInfo, nil
+	validator := httpValidators{
+		ETag:         updateInfoResponse.Header.Get("ETag"),
+		LastModified: updateInfoResponse.Header.Get("Last-Modified"),
+	}
+	return updateInfo, validator, false, nil
 }
 
-func (u *updater) getUpdateInfoURI() (string, error) {
+func (u *updater) getUpdateInfoURI(arch string) (string, error) {
 	// Download mirror.list
-	mirrorListResponse, err := httputil.GetWithUserAgent(u.MirrorListURI)
+	mirrorListResponse, err := httputil.GetWithUserAgent(fmt.Sprintf(u.MirrorListURITmpl, arch))
 	if err != nil {
 		log.WithError(err).Error("could not download mirror list")
 		return "", commonerr.ErrCouldNotDownload
@@ -255,24 +493,39 @@ func decodeUpdateInfo(updateInfoReader io.Reader) (UpdateInfo, error) {
 	return updateInfo, nil
 }
 
-func (u *updater) alasListToVulnerabilities(alasList []ALAS) []database.VulnerabilityWithAffected {
-	var vulnerabilities []database.VulnerabilityWithAffected
+// mergeAlasListToVulnerabilities converts alasList (fetched for the given
+// arch) into vulnerabilities and merges them, by ALAS id, into the
+// accumulator so that ALASes seen across multiple architectures end up as a
+// single database.VulnerabilityWithAffected with one AffectedFeature per arch.
+func (u *updater) mergeAlasListToVulnerabilities(alasList []ALAS, arch string, acc map[string]*database.VulnerabilityWithAffected) {
 	for _, alas := range alasList {
-		featureVersions := u.alasToFeatureVersions(alas)
-		if len(featureVersions) > 0 {
-			vulnerability := database.VulnerabilityWithAffected{
+		featureVersions := u.alasToFeatureVersions(alas, arch)
+		if len(featureVersions) == 0 {
+			continue
+		}
+
+		name := u.alasToName(alas)
+		vulnerability, ok := acc[name]
+		if !ok {
+			vulnerability = &database.VulnerabilityWithAffected{
 				Vulnerability: database.Vulnerability{
-					Name:        u.alasToName(alas),
+					Name:        name,
 					Link:        u.alasToLink(alas),
 					Severity:    u.alasToSeverity(alas),
 					Description: u.alasToDescription(alas),
 				},
-				Affected: featureVersions,
 			}
-			vulnerabilities = append(vulnerabilities, vulnerability)
+			acc[name] = vulnerability
 		}
+		vulnerability.Affected = append(vulnerability.Affected, featureVersions...)
 	}
+}
 
+func flattenVulnerabilities(acc map[string]*database.VulnerabilityWithAffected) []database.VulnerabilityWithAffected {
+	var vulnerabilities []database.VulnerabilityWithAffected
+	for _, v := range acc {
+		vulnerabilities = append(vulnerabilities, *v)
+	}
 	return vulnerabilities
 }
 
@@ -281,17 +534,21 @@ func (u *updater) alasToName(alas ALAS) string {
 }
 
 func (u *updater) alasToLink(alas ALAS) string {
-	if u.Name == amazonLinux1Name {
+	re, ok := alasLinkRewrites[u.Name]
+	if !ok {
+		// Amazon Linux 2018.03's ALAS ids already match their advisory URLs,
+		// e.g. "ALAS-2018-1097".
 		return fmt.Sprintf(u.LinkFormat, alas.Id)
 	}
 
-	if u.Name == amazonLinux2Name {
-		// "ALAS2-2018-1097" becomes "https://alas.aws.amazon.com/AL2/ALAS-2018-1097.html".
-		re := regexp.MustCompile(`^ALAS2-(.+)$`)
-		return fmt.Sprintf(u.LinkFormat, "ALAS-"+re.FindStringSubmatch(alas.Id)[1])
+	// e.g. "ALAS2023-2023-123" becomes
+	// "https://alas.aws.amazon.com/AL2023/ALAS-2023-123.html".
+	matches := re.FindStringSubmatch(alas.Id)
+	if matches == nil {
+		log.WithField("id", alas.Id).Warning("ALAS id did not match the expected format. skipping link rewrite")
+		return ""
 	}
-
-	return ""
+	return fmt.Sprintf(u.LinkFormat, "ALAS-"+matches[1])
 }
 
 func (u *updater) alasToSeverity(alas ALAS) database.Severity {
@@ -315,9 +572,15 @@ func (u *updater) alasToDescription(alas ALAS) string {
 	return re.ReplaceAllString(strings.TrimSpace(alas.Description), " ")
 }
 
-func (u *updater) alasToFeatureVersions(alas ALAS) []database.AffectedFeature {
+func (u *updater) alasToFeatureVersions(alas ALAS, arch string) []database.AffectedFeature {
 	var featureVersions []database.AffectedFeature
 	for _, p := range alas.Packages {
+		// Only RPMs published for the architecture we fetched updateinfo.xml.gz
+		// for are relevant here; skip the rest (e.g. "noarch" is always kept).
+		if p.Arch != "" && p.Arch != arch && p.Arch != "noarch" {
+			continue
+		}
+
 		var version string
 		if p.Epoch == "0" {
 			version = p.Version + "-" + p.Release
@@ -332,7 +595,7 @@ func (u *updater) alasToFeatureVersions(alas ALAS) []database.AffectedFeature {
 
 		featureVersion := database.AffectedFeature{
 			Namespace: database.Namespace{
-				Name:          u.Namespace,
+				Name:          u.namespaceForArch(arch),
 				VersionFormat: rpm.ParserName,
 			},
 			FeatureName:     p.Name,
@@ -340,6 +603,10 @@ func (u *updater) alasToFeatureVersions(alas ALAS) []database.AffectedFeature {
 			FeatureType:     database.BinaryPackage,
 		}
 
+		if qualifiers, ok := purl.Qualifiers(featureVersion.Namespace.Name); ok {
+			featureVersion.PURLQualifier = qualifiers
+		}
+
 		if version != versionfmt.MaxVersion {
 			featureVersion.FixedInVersion = version
 		}
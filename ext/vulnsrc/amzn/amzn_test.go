@@ -0,0 +1,185 @@
+// Copyright 2019 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amzn
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/clair/database"
+)
+
+func TestEncodeDecodeFlag(t *testing.T) {
+	want := map[string]httpValidators{
+		"x86_64":  {ETag: `"etag-x86_64"`, LastModified: "Wed, 21 Oct 2015 07:28:00 GMT", Since: "2020-10-01 00:00"},
+		"aarch64": {ETag: `"etag-aarch64"`, LastModified: "Wed, 21 Oct 2015 07:29:00 GMT", Since: "2020-09-15 12:00"},
+	}
+
+	validators, legacyTimestamp := decodeFlag(encodeFlag(want))
+	if legacyTimestamp != "" {
+		t.Errorf("legacyTimestamp = %q, want empty (current format carries no top-level timestamp)", legacyTimestamp)
+	}
+	if !reflect.DeepEqual(validators, want) {
+		t.Errorf("validators = %+v, want %+v", validators, want)
+	}
+}
+
+func TestDecodeFlagBareTimestamp(t *testing.T) {
+	validators, legacyTimestamp := decodeFlag("2020-10-01 00:00")
+	if legacyTimestamp != "2020-10-01 00:00" {
+		t.Errorf("legacyTimestamp = %q, want %q", legacyTimestamp, "2020-10-01 00:00")
+	}
+	if len(validators) != 0 {
+		t.Errorf("validators = %+v, want empty", validators)
+	}
+}
+
+func TestDecodeFlagEmpty(t *testing.T) {
+	validators, legacyTimestamp := decodeFlag("")
+	if legacyTimestamp != "" {
+		t.Errorf("legacyTimestamp = %q, want empty", legacyTimestamp)
+	}
+	if len(validators) != 0 {
+		t.Errorf("validators = %+v, want empty", validators)
+	}
+}
+
+func TestArches(t *testing.T) {
+	const envVar = "VULNSRC_AMZN_TEST_ARCHES"
+	defer os.Unsetenv(envVar)
+
+	os.Unsetenv(envVar)
+	if got := arches(envVar); !reflect.DeepEqual(got, defaultArches) {
+		t.Errorf("arches() with unset env = %v, want %v", got, defaultArches)
+	}
+
+	os.Setenv(envVar, "x86_64, aarch64 ,  ")
+	if got, want := arches(envVar), []string{"x86_64", "aarch64"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("arches() = %v, want %v", got, want)
+	}
+
+	os.Setenv(envVar, " , ")
+	if got := arches(envVar); !reflect.DeepEqual(got, defaultArches) {
+		t.Errorf("arches() with only blank entries = %v, want %v (fall back to defaultArches)", got, defaultArches)
+	}
+}
+
+func TestNamespaceForArch(t *testing.T) {
+	u := &updater{Namespace: "amzn:2"}
+
+	if got := u.namespaceForArch("x86_64"); got != "amzn:2" {
+		t.Errorf("namespaceForArch(primaryArch) = %q, want %q", got, "amzn:2")
+	}
+	if got := u.namespaceForArch("aarch64"); got != "amzn:2:aarch64" {
+		t.Errorf("namespaceForArch(aarch64) = %q, want %q", got, "amzn:2:aarch64")
+	}
+}
+
+func TestAlasToFeatureVersionsFiltersByArch(t *testing.T) {
+	u := &updater{Namespace: "amzn:2"}
+	alas := ALAS{
+		Packages: []Package{
+			{Name: "kernel", Epoch: "0", Version: "4.14.251", Release: "185.368.amzn2", Arch: "x86_64"},
+			{Name: "kernel", Epoch: "0", Version: "4.14.251", Release: "185.368.amzn2", Arch: "aarch64"},
+			{Name: "noarch-pkg", Epoch: "0", Version: "1.0", Release: "1", Arch: "noarch"},
+		},
+	}
+
+	got := u.alasToFeatureVersions(alas, "x86_64")
+	var names []string
+	for _, f := range got {
+		names = append(names, f.FeatureName+"/"+f.Namespace.Name)
+	}
+
+	want := []string{"kernel/amzn:2", "noarch-pkg/amzn:2"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("alasToFeatureVersions(x86_64) features = %v, want %v", names, want)
+	}
+}
+
+func TestMergeAlasListToVulnerabilitiesMergesAcrossArches(t *testing.T) {
+	u := &updater{Namespace: "amzn:2", Name: amazonLinux2Name, LinkFormat: amazonLinux2LinkFormat}
+	alas := ALAS{
+		Id: "ALAS2-2019-1187",
+		Packages: []Package{
+			{Name: "kernel", Epoch: "0", Version: "4.14.251", Release: "185.368.amzn2", Arch: "x86_64"},
+		},
+	}
+	alasAarch64 := alas
+	alasAarch64.Packages = []Package{
+		{Name: "kernel", Epoch: "0", Version: "4.14.251", Release: "185.368.amzn2", Arch: "aarch64"},
+	}
+
+	acc := make(map[string]*database.VulnerabilityWithAffected)
+	u.mergeAlasListToVulnerabilities([]ALAS{alas}, "x86_64", acc)
+	u.mergeAlasListToVulnerabilities([]ALAS{alasAarch64}, "aarch64", acc)
+
+	if len(acc) != 1 {
+		t.Fatalf("len(acc) = %d, want 1 (same ALAS id across arches should merge)", len(acc))
+	}
+	vuln := acc["ALAS2-2019-1187"]
+	if len(vuln.Affected) != 2 {
+		t.Fatalf("len(Affected) = %d, want 2 (one AffectedFeature per arch)", len(vuln.Affected))
+	}
+
+	vulnerabilities := flattenVulnerabilities(acc)
+	if len(vulnerabilities) != 1 {
+		t.Errorf("len(flattenVulnerabilities(acc)) = %d, want 1", len(vulnerabilities))
+	}
+}
+
+func TestAlasToLink(t *testing.T) {
+	tests := []struct {
+		name       string
+		updaterCfg updater
+		alasID     string
+		want       string
+	}{
+		{
+			name:       "amzn1 ids are already in link format",
+			updaterCfg: updater{Name: amazonLinux1Name, LinkFormat: amazonLinux1LinkFormat},
+			alasID:     "ALAS-2018-1097",
+			want:       "https://alas.aws.amazon.com/ALAS-2018-1097.html",
+		},
+		{
+			name:       "amzn2 ids are rewritten from ALAS2- to ALAS-",
+			updaterCfg: updater{Name: amazonLinux2Name, LinkFormat: amazonLinux2LinkFormat},
+			alasID:     "ALAS2-2019-1187",
+			want:       "https://alas.aws.amazon.com/AL2/ALAS-2019-1187.html",
+		},
+		{
+			name:       "amzn2022 ids are rewritten from ALAS2022- to ALAS-",
+			updaterCfg: updater{Name: amazonLinux2022Name, LinkFormat: amazonLinux2022LinkFormat},
+			alasID:     "ALAS2022-2022-123",
+			want:       "https://alas.aws.amazon.com/AL2022/ALAS-2022-123.html",
+		},
+		{
+			name:       "amzn2023 ids are rewritten from ALAS2023- to ALAS-",
+			updaterCfg: updater{Name: amazonLinux2023Name, LinkFormat: amazonLinux2023LinkFormat},
+			alasID:     "ALAS2023-2023-456",
+			want:       "https://alas.aws.amazon.com/AL2023/ALAS-2023-456.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := tt.updaterCfg
+			if got := u.alasToLink(ALAS{Id: tt.alasID}); got != tt.want {
+				t.Errorf("alasToLink(%q) = %q, want %q", tt.alasID, got, tt.want)
+			}
+		})
+	}
+}
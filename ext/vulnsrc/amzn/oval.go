@@ -0,0 +1,325 @@
+// Copyright 2019 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amzn
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/coreos/clair/database"
+	"github.com/coreos/clair/ext/versionfmt"
+	"github.com/coreos/clair/ext/versionfmt/rpm"
+	"github.com/coreos/clair/ext/vulnsrc/purl"
+	"github.com/coreos/clair/pkg/commonerr"
+	"github.com/coreos/clair/pkg/httputil"
+)
+
+// amazonLinux2OvalURI is the OVAL feed covering the same advisories as
+// amazonLinux2MirrorListURI's updateinfo.xml.gz, but with CVSSv3 and CWE
+// metadata attached.
+const amazonLinux2OvalURI = "https://alas.aws.amazon.com/AL2/alas.xml"
+
+// Values accepted by VULNSRC_AMZN2_SOURCE.
+const (
+	sourceUpdateInfo = "updateinfo"
+	sourceOval       = "oval"
+)
+
+// ovalDefinitions is the root element of an Amazon Linux alas.xml document.
+type ovalDefinitions struct {
+	XMLName     xml.Name     `xml:"oval_definitions"`
+	Definitions []ovalDef    `xml:"definitions>definition"`
+	Tests       []ovalTest   `xml:"tests>rpminfo_test"`
+	Objects     []ovalObject `xml:"objects>rpminfo_object"`
+	States      []ovalState  `xml:"states>rpminfo_state"`
+}
+
+type ovalDef struct {
+	Metadata ovalMetadata `xml:"metadata"`
+	Criteria ovalCriteria `xml:"criteria"`
+}
+
+type ovalMetadata struct {
+	Title       string       `xml:"title"`
+	Description string       `xml:"description"`
+	Advisory    ovalAdvisory `xml:"advisory"`
+	References  []ovalRefXML `xml:"reference"`
+}
+
+type ovalRefXML struct {
+	Source string `xml:"source,attr"`
+	RefID  string `xml:"ref_id,attr"`
+	RefURL string `xml:"ref_url,attr"`
+}
+
+type ovalAdvisory struct {
+	Severity string    `xml:"severity"`
+	Cves     []ovalCVE `xml:"cve"`
+}
+
+// ovalCVE captures the CVSSv3 and CWE information Amazon attaches to each
+// CVE referenced by an advisory, which updateinfo.xml.gz does not carry.
+type ovalCVE struct {
+	CveID string `xml:",chardata"`
+	Cvss3 string `xml:"cvss3,attr"`
+	Cwe   string `xml:"cwe,attr"`
+	Href  string `xml:"href,attr"`
+}
+
+type ovalCriteria struct {
+	Operator   string          `xml:"operator,attr"`
+	Criterions []ovalCriterion `xml:"criterion"`
+	Criterias  []ovalCriteria  `xml:"criteria"`
+}
+
+type ovalCriterion struct {
+	TestRef string `xml:"test_ref,attr"`
+	Comment string `xml:"comment,attr"`
+}
+
+type ovalTest struct {
+	ID        string `xml:"id,attr"`
+	ObjectRef struct {
+		ObjectRef string `xml:"object_ref,attr"`
+	} `xml:"object"`
+	StateRef struct {
+		StateRef string `xml:"state_ref,attr"`
+	} `xml:"state"`
+}
+
+type ovalObject struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name"`
+}
+
+type ovalState struct {
+	ID  string `xml:"id,attr"`
+	EVR struct {
+		Value     string `xml:",chardata"`
+		Operation string `xml:"operation,attr"`
+	} `xml:"evr"`
+}
+
+// metadataForCVE builds the per-CVE Metadata entry the matcher renders as
+// severity/CWE independently of alasToSeverity's coarse mapping.
+func metadataForCVE(cve ovalCVE) map[string]interface{} {
+	md := make(map[string]interface{})
+	if cve.Cvss3 != "" {
+		md["CVSSv3"] = map[string]interface{}{
+			"Vectors": cve.Cvss3,
+			"Score":   cvss3Score(cve.Cvss3),
+		}
+	}
+	if cve.Cwe != "" {
+		md["CWE"] = cve.Cwe
+	}
+	return md
+}
+
+// metadataForCVEs aggregates every CVE an OVAL definition references into a
+// single Vulnerability Metadata map, keyed by CVE id. A definition's Name is
+// the bare ALAS id so it can be deduped against the same advisory fetched
+// from updateinfo.xml.gz (see alasIDFromTitle), and an ALAS routinely
+// references several CVEs with distinct CVSSv3 scores - keeping only the
+// first, as a naive `Cves[0]` would, silently drops the rest.
+func metadataForCVEs(cves []ovalCVE) map[string]interface{} {
+	if len(cves) == 0 {
+		return nil
+	}
+	md := make(map[string]interface{}, len(cves))
+	for _, cve := range cves {
+		md[cve.CveID] = metadataForCVE(cve)
+	}
+	return md
+}
+
+// alasIDFromTitle extracts the bare ALAS id (e.g. "ALAS2-2019-1187") from an
+// OVAL definition's title (e.g. "ALAS2-2019-1187: kernel security update"),
+// so that Name, the dedup/merge key, agrees with alasToName's output from
+// the updateinfo.xml.gz path regardless of which source
+// VULNSRC_AMZN2_SOURCE selects.
+func alasIDFromTitle(title string) string {
+	id := strings.SplitN(title, ":", 2)[0]
+	return strings.TrimSpace(id)
+}
+
+// cvss3Score extracts the base score Amazon embeds at the front of a CVSSv3
+// vector string, e.g. "7.8/CVSS:3.1/AV:L/AC:L/...".
+func cvss3Score(vector string) float64 {
+	fields := strings.SplitN(vector, "/", 2)
+	if len(fields) == 0 {
+		return 0
+	}
+	score, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return score
+}
+
+// fetchOval downloads and decodes the Amazon Linux 2 OVAL feed.
+func (u *updater) fetchOval() (ovalDefinitions, error) {
+	resp, err := httputil.GetWithUserAgent(amazonLinux2OvalURI)
+	if err != nil {
+		log.WithError(err).Error("could not download alas.xml")
+		return ovalDefinitions{}, commonerr.ErrCouldNotDownload
+	}
+	defer resp.Body.Close()
+
+	if !httputil.Status2xx(resp) {
+		log.WithField("StatusCode", resp.StatusCode).Error("could not download alas.xml")
+		return ovalDefinitions{}, commonerr.ErrCouldNotDownload
+	}
+
+	var defs ovalDefinitions
+	if err := xml.NewDecoder(resp.Body).Decode(&defs); err != nil {
+		log.WithError(err).Error("could not decode alas.xml")
+		return ovalDefinitions{}, commonerr.ErrCouldNotParse
+	}
+	return defs, nil
+}
+
+// updateFromOval is the VULNSRC_AMZN2_SOURCE=oval counterpart of Update: it
+// produces the same []database.VulnerabilityWithAffected as the
+// updateinfo.xml.gz path, plus CVSSv3/CWE Metadata per vulnerability.
+func (u *updater) updateFromOval() ([]database.VulnerabilityWithAffected, error) {
+	defs, err := u.fetchOval()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string]ovalObject, len(defs.Objects))
+	for _, o := range defs.Objects {
+		objects[o.ID] = o
+	}
+	states := make(map[string]ovalState, len(defs.States))
+	for _, s := range defs.States {
+		states[s.ID] = s
+	}
+	tests := make(map[string]ovalTest, len(defs.Tests))
+	for _, t := range defs.Tests {
+		tests[t.ID] = t
+	}
+
+	var vulnerabilities []database.VulnerabilityWithAffected
+	for _, def := range defs.Definitions {
+		affected := u.ovalDefToFeatureVersions(def, objects, states, tests)
+		if len(affected) == 0 {
+			continue
+		}
+
+		vulnerability := database.VulnerabilityWithAffected{
+			Vulnerability: database.Vulnerability{
+				Name:        alasIDFromTitle(def.Metadata.Title),
+				Link:        u.ovalAdvisoryLink(def),
+				Severity:    u.alasSeverityFromOval(def.Metadata.Advisory.Severity),
+				Description: strings.TrimSpace(def.Metadata.Description),
+			},
+			Affected: affected,
+		}
+		if md := metadataForCVEs(def.Metadata.Advisory.Cves); md != nil {
+			vulnerability.Metadata = md
+		}
+		vulnerabilities = append(vulnerabilities, vulnerability)
+	}
+
+	return vulnerabilities, nil
+}
+
+func (u *updater) ovalAdvisoryLink(def ovalDef) string {
+	for _, ref := range def.Metadata.References {
+		if ref.Source == "ALAS" {
+			return ref.RefURL
+		}
+	}
+	return ""
+}
+
+func (u *updater) alasSeverityFromOval(severity string) database.Severity {
+	return u.alasToSeverity(ALAS{Severity: strings.ToLower(severity)})
+}
+
+// archFromComment returns whichever of u.Arches is named in an OVAL
+// criterion's comment (e.g. "kernel is earlier than 0:4.14.251-185.369.amzn2
+// on aarch64"), or primaryArch if none is, so a criterion that doesn't call
+// out a non-default architecture is filed under the updater's base
+// namespace like it always has been.
+func (u *updater) archFromComment(comment string) string {
+	for _, arch := range u.Arches {
+		if arch != primaryArch && strings.Contains(comment, arch) {
+			return arch
+		}
+	}
+	return primaryArch
+}
+
+func (u *updater) ovalDefToFeatureVersions(def ovalDef, objects map[string]ovalObject, states map[string]ovalState, tests map[string]ovalTest) []database.AffectedFeature {
+	var featureVersions []database.AffectedFeature
+
+	// walk does not evaluate c.Operator (AND/OR): every criterion under a
+	// criteria block is treated as independently sufficient to produce a
+	// feature, even when the feed combines them with AND (e.g. a version
+	// check alongside an unrelated, non-version condition). Evaluating AND
+	// groups correctly would require resolving every sibling criterion's
+	// test before deciding whether the group as a whole matches, which
+	// needs a larger rework of this function; tracked as a known gap.
+	var walk func(c ovalCriteria)
+	walk = func(c ovalCriteria) {
+		for _, criterion := range c.Criterions {
+			test, ok := tests[criterion.TestRef]
+			if !ok {
+				continue
+			}
+			object, ok := objects[test.ObjectRef.ObjectRef]
+			if !ok {
+				continue
+			}
+			state, ok := states[test.StateRef.StateRef]
+			if !ok || state.EVR.Value == "" {
+				continue
+			}
+
+			version := state.EVR.Value
+			if err := versionfmt.Valid(rpm.ParserName, version); err != nil {
+				log.WithError(err).WithField("version", version).Warning("could not parse OVAL package version. skipping")
+				continue
+			}
+
+			featureVersion := database.AffectedFeature{
+				Namespace: database.Namespace{
+					Name:          u.namespaceForArch(u.archFromComment(criterion.Comment)),
+					VersionFormat: rpm.ParserName,
+				},
+				FeatureName:     object.Name,
+				AffectedVersion: version,
+				FixedInVersion:  version,
+				FeatureType:     database.BinaryPackage,
+			}
+			if qualifiers, ok := purl.Qualifiers(featureVersion.Namespace.Name); ok {
+				featureVersion.PURLQualifier = qualifiers
+			}
+			featureVersions = append(featureVersions, featureVersion)
+		}
+		for _, sub := range c.Criterias {
+			walk(sub)
+		}
+	}
+	walk(def.Criteria)
+
+	return featureVersions
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amzn
+
+import "testing"
+
+func TestAlasIDFromTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"ALAS2-2019-1187: kernel security update", "ALAS2-2019-1187"},
+		{"ALAS2023-2023-123:important security update", "ALAS2023-2023-123"},
+		{"ALAS-2018-1097", "ALAS-2018-1097"},
+	}
+
+	for _, tt := range tests {
+		if got := alasIDFromTitle(tt.title); got != tt.want {
+			t.Errorf("alasIDFromTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestMetadataForCVEs(t *testing.T) {
+	cves := []ovalCVE{
+		{CveID: "CVE-2019-1111", Cvss3: "7.8/CVSS:3.1/AV:L/AC:L", Cwe: "CWE-416"},
+		{CveID: "CVE-2019-2222", Cvss3: "5.5/CVSS:3.1/AV:L/AC:L"},
+	}
+
+	md := metadataForCVEs(cves)
+	if len(md) != 2 {
+		t.Fatalf("len(md) = %d, want 2 (one entry per CVE)", len(md))
+	}
+	if _, ok := md["CVE-2019-1111"]; !ok {
+		t.Errorf("md missing entry for CVE-2019-1111")
+	}
+	if _, ok := md["CVE-2019-2222"]; !ok {
+		t.Errorf("md missing entry for CVE-2019-2222")
+	}
+}
+
+func TestArchFromComment(t *testing.T) {
+	u := &updater{Arches: []string{"x86_64", "aarch64"}}
+
+	if got := u.archFromComment("kernel is earlier than 0:4.14.251-185.369.amzn2 on aarch64"); got != "aarch64" {
+		t.Errorf("archFromComment(aarch64 comment) = %q, want %q", got, "aarch64")
+	}
+	if got := u.archFromComment("kernel is earlier than 0:4.14.251-185.369.amzn2"); got != primaryArch {
+		t.Errorf("archFromComment(no-arch comment) = %q, want %q", got, primaryArch)
+	}
+}
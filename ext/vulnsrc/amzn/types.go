@@ -0,0 +1,57 @@
+// Copyright 2019 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amzn
+
+import "encoding/xml"
+
+// UpdateInfo models the updateinfo.xml.gz document published alongside an
+// Amazon Linux repository.
+type UpdateInfo struct {
+	XMLName  xml.Name `xml:"updates"`
+	ALASList []ALAS   `xml:"update"`
+}
+
+// ALAS models a single Amazon Linux Security Advisory as found in
+// updateinfo.xml.gz.
+type ALAS struct {
+	Id          string `xml:"id,attr"`
+	Title       string `xml:"title"`
+	Severity    string `xml:"severity,attr"`
+	Description string `xml:"description"`
+	Updated     struct {
+		Date string `xml:"date,attr"`
+	} `xml:"updated"`
+	Packages []Package `xml:"pkglist>collection>package"`
+}
+
+// Package models a single RPM referenced by an ALAS.
+type Package struct {
+	Name    string `xml:"name,attr"`
+	Epoch   string `xml:"epoch,attr"`
+	Version string `xml:"version,attr"`
+	Release string `xml:"release,attr"`
+	Arch    string `xml:"arch,attr"`
+}
+
+// RepoMd models repomd.xml, which lists the data files (including
+// updateinfo.xml.gz) published by a yum repository.
+type RepoMd struct {
+	RepoList []struct {
+		Type     string `xml:"type,attr"`
+		Location struct {
+			Href string `xml:"href,attr"`
+		} `xml:"location"`
+	} `xml:"data"`
+}
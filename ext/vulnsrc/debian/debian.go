@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/coreos/clair/ext/versionfmt"
 	"github.com/coreos/clair/ext/versionfmt/dpkg"
 	"github.com/coreos/clair/ext/vulnsrc"
+	"github.com/coreos/clair/ext/vulnsrc/purl"
 	"github.com/coreos/clair/pkg/commonerr"
 	"github.com/coreos/clair/pkg/httputil"
 )
@@ -40,13 +42,43 @@ var (
 	url = "https://security-tracker.debian.org/tracker/data/json"
 	// This will be overwritten by os.GetEnv("VULNSRC_DEBIAN_CVEPREFIX") if present
 	cveURLPrefix = "https://security-tracker.debian.org/tracker"
+	// This will be overwritten by os.GetEnv("VULNSRC_DEBIAN_FREEXIAN_JSON") if
+	// present. Freexian keeps tracking fixes, in the same json schema, for
+	// releases that have rolled off Debian's own security-tracker: Buster and
+	// Stretch under the free, community-run LTS, and Jessie/Wheezy under the
+	// paid Extended LTS. See debianELTSReleases.
+	freexianURL = "https://deb.freexian.com/extended-lts/tracker/data/json"
 )
 
 const (
 	updaterFlag  = "debianUpdater"
 	affectedType = database.SourcePackage
+
+	// Support sources surfaced to consumers so they can tell which vendor is
+	// providing a given fix.
+	supportDebianSecurity = "debian-security"
+	supportDebianLTS      = "debian-lts"
+	supportFreexianELTS   = "freexian-elts"
 )
 
+// debianELTSReleases lists the codenames Freexian tracks under its paid
+// Extended LTS programme. Any other release found in freexianURL's data is
+// assumed to be covered by the free community LTS instead.
+var debianELTSReleases = map[string]bool{
+	"wheezy": true,
+	"jessie": true,
+}
+
+func supportSourceFor(releaseName string, fromFreexian bool) string {
+	if !fromFreexian {
+		return supportDebianSecurity
+	}
+	if debianELTSReleases[releaseName] {
+		return supportFreexianELTS
+	}
+	return supportDebianLTS
+}
+
 type jsonData map[string]map[string]jsonVuln
 
 type jsonVuln struct {
@@ -58,6 +90,12 @@ type jsonRel struct {
 	FixedVersion string `json:"fixed_version"`
 	Status       string `json:"status"`
 	Urgency      string `json:"urgency"`
+
+	// source records which tracker this release entry came from. It isn't
+	// part of Debian's json schema; it's set once the entry is merged into
+	// the combined dataset so parseDebianJSON can tell debian-security fixes
+	// apart from ones that only exist because Freexian still tracks them.
+	source string
 }
 
 type updater struct{}
@@ -70,47 +108,108 @@ func init() {
 	if os.Getenv("VULNSRC_DEBIAN_JSON") != "" {
 		url = os.Getenv("VULNSRC_DEBIAN_JSON")
 	}
+	if os.Getenv("VULNSRC_DEBIAN_FREEXIAN_JSON") != "" {
+		freexianURL = os.Getenv("VULNSRC_DEBIAN_FREEXIAN_JSON")
+	}
 
 	vulnsrc.RegisterUpdater("debian", &updater{})
 }
 
 func (u *updater) Update(datastore database.Datastore) (resp vulnsrc.UpdateResponse, err error) {
 	log.WithField("package", "Debian").Info("Start fetching vulnerabilities")
-	latestHash, ok, err := database.FindKeyValueAndRollback(datastore, updaterFlag)
+	flagValue, ok, err := database.FindKeyValueAndRollback(datastore, updaterFlag)
 	if err != nil {
 		return
 	}
 
 	if !ok {
-		latestHash = ""
+		flagValue = ""
 	}
+	latestHash, etag, lastModified := decodeFlag(flagValue)
 
-	// Download JSON.
-	r, err := httputil.GetWithUserAgent(url)
+	// Download JSON, skipping the body entirely if the tracker json hasn't
+	// changed since our last successful fetch.
+	r, err := httputil.GetConditional(url, etag, lastModified)
 	if err != nil {
 		log.WithError(err).Error("could not download Debian's update")
 		return resp, commonerr.ErrCouldNotDownload
 	}
-
 	defer r.Body.Close()
 
+	if httputil.NotModified(r) {
+		log.WithField("package", "Debian").Debug("not modified, skip")
+		resp.FlagName = updaterFlag
+		resp.FlagValue = flagValue
+		return resp, nil
+	}
+
 	if !httputil.Status2xx(r) {
 		log.WithField("StatusCode", r.StatusCode).Error("Failed to update Debian")
 		return resp, commonerr.ErrCouldNotDownload
 	}
 
+	// Freexian's extended-lts/lts tracker covers releases that have rolled
+	// off Debian's own security-tracker. It's supplementary, so a failure to
+	// fetch it shouldn't fail the whole update.
+	freexianJSON, err := fetchFreexianJSON()
+	if err != nil {
+		log.WithError(err).Warning("could not download Freexian's extended-lts tracker; EOL release coverage may be stale")
+		freexianJSON = nil
+	}
+
 	// Parse the JSON.
-	resp, err = buildResponse(r.Body, latestHash)
+	resp, err = buildResponse(r.Body, freexianJSON, latestHash)
 	if err != nil {
 		return resp, err
 	}
 
+	resp.FlagValue = encodeFlag(resp.FlagValue, r.Header.Get("ETag"), r.Header.Get("Last-Modified"))
+
 	return resp, nil
 }
 
+// fetchFreexianJSON downloads Freexian's extended-lts/lts tracker json in
+// full; unlike the main tracker it's small enough that a conditional fetch
+// isn't worth the extra bookkeeping.
+func fetchFreexianJSON() ([]byte, error) {
+	r, err := httputil.GetWithUserAgent(freexianURL)
+	if err != nil {
+		return nil, commonerr.ErrCouldNotDownload
+	}
+	defer r.Body.Close()
+
+	if !httputil.Status2xx(r) {
+		return nil, commonerr.ErrCouldNotDownload
+	}
+
+	return ioutil.ReadAll(r.Body)
+}
+
 func (u *updater) Clean() {}
 
-func buildResponse(jsonReader io.Reader, latestKnownHash string) (resp vulnsrc.UpdateResponse, err error) {
+// encodeFlag packs the tracker json's content hash together with the HTTP
+// validators returned alongside it, so a future Update can issue a
+// conditional GET without a separate key/value entry.
+func encodeFlag(hash, etag, lastModified string) string {
+	return strings.Join([]string{hash, etag, lastModified}, "|")
+}
+
+// decodeFlag reverses encodeFlag. It also accepts a bare hash (or the empty
+// string) for compatibility with flags written before conditional fetches
+// were introduced.
+func decodeFlag(flag string) (hash, etag, lastModified string) {
+	parts := strings.SplitN(flag, "|", 3)
+	hash = parts[0]
+	if len(parts) > 1 {
+		etag = parts[1]
+	}
+	if len(parts) > 2 {
+		lastModified = parts[2]
+	}
+	return
+}
+
+func buildResponse(jsonReader io.Reader, freexianJSON []byte, latestKnownHash string) (resp vulnsrc.UpdateResponse, err error) {
 	hash := latestKnownHash
 
 	// Defer the addition of flag information to the response.
@@ -122,7 +221,10 @@ func buildResponse(jsonReader io.Reader, latestKnownHash string) (resp vulnsrc.U
 	}()
 
 	// Create a TeeReader so that we can unmarshal into JSON and write to a hash
-	// digest at the same time.
+	// digest at the same time. freexianJSON is folded into the same digest so
+	// an EOL-only update isn't skipped by the hash check when the main tracker
+	// json is re-downloaded unchanged. A 304 from the main tracker makes
+	// Update return before Freexian's json is fetched at all.
 	jsonSHA := sha256.New()
 	teedJSONReader := io.TeeReader(jsonReader, jsonSHA)
 
@@ -133,6 +235,25 @@ func buildResponse(jsonReader io.Reader, latestKnownHash string) (resp vulnsrc.U
 		log.WithError(err).Error("could not unmarshal Debian's JSON")
 		return resp, commonerr.ErrCouldNotParse
 	}
+	for _, pkgNode := range data {
+		for _, vulnNode := range pkgNode {
+			for release, rel := range vulnNode.Releases {
+				rel.source = supportDebianSecurity
+				vulnNode.Releases[release] = rel
+			}
+		}
+	}
+
+	if len(freexianJSON) > 0 {
+		jsonSHA.Write(freexianJSON)
+
+		var freexian jsonData
+		if err := json.Unmarshal(freexianJSON, &freexian); err != nil {
+			log.WithError(err).Warning("could not unmarshal Freexian's extended-lts JSON; skipping EOL coverage")
+		} else {
+			mergeFreexianJSON(&data, &freexian)
+		}
+	}
 
 	// Calculate the hash and skip updating if the hash has been seen before.
 	hash = hex.EncodeToString(jsonSHA.Sum(nil))
@@ -155,6 +276,66 @@ func buildResponse(jsonReader io.Reader, latestKnownHash string) (resp vulnsrc.U
 	return resp, nil
 }
 
+// isDeterminate reports whether rel carries an actual version verdict -
+// "open" (still vulnerable) or "resolved" with a FixedVersion dpkg can
+// parse into something other than the "0" sentinel. Debian's tracker keeps
+// a stub entry (e.g. status "undetermined", or a "resolved" entry whose
+// FixedVersion is the unbackfilled "0" placeholder - see the FixedVersion
+// != "0" check in parseDebianJSON) for releases long after they've gone
+// EOL rather than deleting the key, so a release can be present in primary
+// without primary actually having anything useful to say about it.
+func isDeterminate(rel jsonRel) bool {
+	switch rel.Status {
+	case "open":
+		return true
+	case "resolved":
+		return rel.FixedVersion != "0" && versionfmt.Valid(dpkg.ParserName, rel.FixedVersion) == nil
+	default:
+		return false
+	}
+}
+
+// mergeFreexianJSON folds freexian's releases into primary wherever primary
+// doesn't already have a determinate verdict for that (package, CVE,
+// release) itself, i.e. wherever Debian's own security-tracker has stopped
+// meaningfully reporting on a release because it went end-of-life. Debian's
+// tracker is authoritative for releases it still actively covers, so a
+// release with a determinate entry in both keeps primary's; an
+// undetermined/EOL-stub entry in primary is replaced by freexian's, tagged
+// with the support source (LTS or ELTS, per supportSourceFor) matching the
+// release's own support tier.
+func mergeFreexianJSON(primary, freexian *jsonData) {
+	for pkgName, pkgNode := range *freexian {
+		primaryPkgNode, ok := (*primary)[pkgName]
+		if !ok {
+			primaryPkgNode = make(map[string]jsonVuln)
+			(*primary)[pkgName] = primaryPkgNode
+		}
+
+		for vulnName, vulnNode := range pkgNode {
+			primaryVulnNode, ok := primaryPkgNode[vulnName]
+			if !ok {
+				primaryVulnNode = jsonVuln{
+					Description: vulnNode.Description,
+					Releases:    make(map[string]jsonRel),
+				}
+			} else if primaryVulnNode.Releases == nil {
+				primaryVulnNode.Releases = make(map[string]jsonRel)
+			}
+
+			for releaseName, releaseNode := range vulnNode.Releases {
+				if existing, coveredByPrimary := primaryVulnNode.Releases[releaseName]; coveredByPrimary && isDeterminate(existing) {
+					continue
+				}
+				releaseNode.source = supportSourceFor(releaseName, true)
+				primaryVulnNode.Releases[releaseName] = releaseNode
+			}
+
+			primaryPkgNode[vulnName] = primaryVulnNode
+		}
+	}
+}
+
 func parseDebianJSON(data *jsonData) (vulnerabilities []database.VulnerabilityWithAffected, unknownReleases map[string]struct{}) {
 	mvulnerabilities := make(map[string]*database.VulnerabilityWithAffected)
 	unknownReleases = make(map[string]struct{})
@@ -233,11 +414,15 @@ func parseDebianJSON(data *jsonData) (vulnerabilities []database.VulnerabilityWi
 					FeatureName:     pkgName,
 					AffectedVersion: version,
 					FixedInVersion:  fixedInVersion,
+					SupportSource:   releaseNode.source,
 					Namespace: database.Namespace{
 						Name:          "debian:" + database.DebianReleasesMapping[releaseName],
 						VersionFormat: dpkg.ParserName,
 					},
 				}
+				if qualifiers, ok := purl.Qualifiers(pkg.Namespace.Name); ok {
+					pkg.PURLQualifier = qualifiers
+				}
 				vulnerability.Affected = append(vulnerability.Affected, pkg)
 
 				// Store the vulnerability.
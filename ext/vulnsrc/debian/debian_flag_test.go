@@ -0,0 +1,37 @@
+// Copyright 2019 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debian
+
+import "testing"
+
+func TestEncodeDecodeFlag(t *testing.T) {
+	hash, etag, lastModified := decodeFlag(encodeFlag("abc123", `"etag-value"`, "Wed, 21 Oct 2015 07:28:00 GMT"))
+	if hash != "abc123" {
+		t.Errorf("hash = %q, want %q", hash, "abc123")
+	}
+	if etag != `"etag-value"` {
+		t.Errorf("etag = %q, want %q", etag, `"etag-value"`)
+	}
+	if lastModified != "Wed, 21 Oct 2015 07:28:00 GMT" {
+		t.Errorf("lastModified = %q, want %q", lastModified, "Wed, 21 Oct 2015 07:28:00 GMT")
+	}
+}
+
+func TestDecodeFlagBareHash(t *testing.T) {
+	hash, etag, lastModified := decodeFlag("abc123")
+	if hash != "abc123" || etag != "" || lastModified != "" {
+		t.Errorf("decodeFlag(%q) = (%q, %q, %q), want (\"abc123\", \"\", \"\")", "abc123", hash, etag, lastModified)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debian
+
+import "testing"
+
+// TestMergeFreexianJSONSkipsDeterminatePrimary verifies that when Debian's
+// own security-tracker already has a determinate verdict for a release,
+// Freexian's entry for that same release is dropped.
+func TestMergeFreexianJSONSkipsDeterminatePrimary(t *testing.T) {
+	primary := &jsonData{
+		"pkg": {
+			"CVE-2020-0001": jsonVuln{
+				Releases: map[string]jsonRel{
+					"bullseye": {Status: "resolved", FixedVersion: "1.2.3"},
+				},
+			},
+		},
+	}
+	freexian := &jsonData{
+		"pkg": {
+			"CVE-2020-0001": jsonVuln{
+				Releases: map[string]jsonRel{
+					"bullseye": {Status: "resolved", FixedVersion: "9.9.9"},
+				},
+			},
+		},
+	}
+
+	mergeFreexianJSON(primary, freexian)
+
+	rel := (*primary)["pkg"]["CVE-2020-0001"].Releases["bullseye"]
+	if rel.FixedVersion != "1.2.3" {
+		t.Errorf("FixedVersion = %q, want %q (primary's determinate entry should win)", rel.FixedVersion, "1.2.3")
+	}
+}
+
+// TestMergeFreexianJSONFillsEOLStub verifies that a stub entry Debian's
+// tracker keeps around for an EOL release (undetermined status, no real
+// fix) is replaced by Freexian's real data, tagged with the release's
+// support tier.
+func TestMergeFreexianJSONFillsEOLStub(t *testing.T) {
+	primary := &jsonData{
+		"pkg": {
+			"CVE-2020-0002": jsonVuln{
+				Releases: map[string]jsonRel{
+					"wheezy": {Status: "undetermined"},
+				},
+			},
+		},
+	}
+	freexian := &jsonData{
+		"pkg": {
+			"CVE-2020-0002": jsonVuln{
+				Releases: map[string]jsonRel{
+					"wheezy": {Status: "resolved", FixedVersion: "1.0-1+deb7u1"},
+				},
+			},
+		},
+	}
+
+	mergeFreexianJSON(primary, freexian)
+
+	rel := (*primary)["pkg"]["CVE-2020-0002"].Releases["wheezy"]
+	if rel.FixedVersion != "1.0-1+deb7u1" {
+		t.Errorf("FixedVersion = %q, want %q (Freexian should fill an EOL stub)", rel.FixedVersion, "1.0-1+deb7u1")
+	}
+	if rel.source != supportFreexianELTS {
+		t.Errorf("source = %q, want %q (wheezy is an ELTS release)", rel.source, supportFreexianELTS)
+	}
+}
+
+// TestMergeFreexianJSONFillsUnbackfilledResolvedStub verifies that a
+// "resolved" entry whose FixedVersion is still the "0" sentinel - i.e.
+// Debian's tracker never actually backfilled a real fix - is not treated
+// as determinate, so Freexian's real data replaces it instead of being
+// dropped.
+func TestMergeFreexianJSONFillsUnbackfilledResolvedStub(t *testing.T) {
+	primary := &jsonData{
+		"pkg": {
+			"CVE-2020-0003": jsonVuln{
+				Releases: map[string]jsonRel{
+					"wheezy": {Status: "resolved", FixedVersion: "0"},
+				},
+			},
+		},
+	}
+	freexian := &jsonData{
+		"pkg": {
+			"CVE-2020-0003": jsonVuln{
+				Releases: map[string]jsonRel{
+					"wheezy": {Status: "resolved", FixedVersion: "1.0-1+deb7u2"},
+				},
+			},
+		},
+	}
+
+	mergeFreexianJSON(primary, freexian)
+
+	rel := (*primary)["pkg"]["CVE-2020-0003"].Releases["wheezy"]
+	if rel.FixedVersion != "1.0-1+deb7u2" {
+		t.Errorf("FixedVersion = %q, want %q (Freexian should fill an unbackfilled \"0\" stub)", rel.FixedVersion, "1.0-1+deb7u2")
+	}
+}
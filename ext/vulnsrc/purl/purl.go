@@ -0,0 +1,128 @@
+// Copyright 2019 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package purl converts between Clair's internal vulnerability namespaces
+// (e.g. "amzn:2", "debian:11") and the "distro" qualifier used by Package
+// URLs (https://github.com/package-url/purl-spec), e.g.
+// "pkg:rpm/amzn/openssl@1.1.1-...?distro=amzn-2&arch=x86_64". This lets a
+// scanner that only holds a PURL resolve the namespace a vulnerability
+// source filed its data under, without re-implementing the distro table
+// that otherwise lives implicitly in database.DebianReleasesMapping and the
+// amzn package's namespace constants.
+package purl
+
+import (
+	"net/url"
+	"strings"
+)
+
+// distroQualifiers maps a Clair namespace (without an arch suffix) to the
+// PURL distro qualifier used to identify the same OS release.
+var distroQualifiers = map[string]string{
+	"amzn:2018.03": "amzn-2018.03",
+	"amzn:2":       "amzn-2",
+	"amzn:2022":    "amzn-2022",
+	"amzn:2023":    "amzn-2023",
+
+	"debian:7":  "debian-7",
+	"debian:8":  "debian-8",
+	"debian:9":  "debian-9",
+	"debian:10": "debian-10",
+	"debian:11": "debian-11",
+	"debian:12": "debian-12",
+}
+
+// namespacesByDistro is the reverse of distroQualifiers, built once at
+// package init.
+var namespacesByDistro = func() map[string]string {
+	m := make(map[string]string, len(distroQualifiers))
+	for namespace, distro := range distroQualifiers {
+		m[distro] = namespace
+	}
+	return m
+}()
+
+// DistroQualifier returns the PURL distro qualifier for a Clair namespace,
+// e.g. "amzn:2:aarch64" -> "amzn-2" (the architecture is carried separately,
+// in the PURL's own "arch" qualifier; see ArchQualifier).
+func DistroQualifier(namespace string) (string, bool) {
+	base, _ := splitArch(namespace)
+	distro, ok := distroQualifiers[base]
+	return distro, ok
+}
+
+// ArchQualifier returns the PURL arch qualifier, if any, embedded in an
+// arch-qualified amzn namespace such as "amzn:2:aarch64".
+func ArchQualifier(namespace string) (string, bool) {
+	_, arch := splitArch(namespace)
+	return arch, arch != ""
+}
+
+// splitArch separates an arch-qualified amzn namespace (e.g.
+// "amzn:2:aarch64") into its base namespace and architecture. Namespaces
+// without an arch suffix, such as Debian's, return an empty architecture.
+func splitArch(namespace string) (base, arch string) {
+	parts := strings.SplitN(namespace, ":", 3)
+	if len(parts) == 3 {
+		return parts[0] + ":" + parts[1], parts[2]
+	}
+	return namespace, ""
+}
+
+// Qualifiers returns the PURL qualifier string, with keys sorted (e.g.
+// "distro=amzn-2" or "arch=aarch64&distro=amzn-2"), for a Clair namespace, ready to append to a
+// "pkg:.../name@version?" PURL. It returns false if the namespace has no
+// distro mapping.
+func Qualifiers(namespace string) (string, bool) {
+	distro, ok := DistroQualifier(namespace)
+	if !ok {
+		return "", false
+	}
+
+	qualifiers := url.Values{"distro": []string{distro}}
+	if arch, ok := ArchQualifier(namespace); ok {
+		qualifiers.Set("arch", arch)
+	}
+
+	return qualifiers.Encode(), true
+}
+
+// NamespaceForPURL resolves the Clair namespace that a PURL's "distro" (and,
+// for amzn, "arch") qualifier corresponds to. It returns false if the PURL
+// has no distro qualifier we recognize.
+func NamespaceForPURL(purl string) (string, bool) {
+	u, err := url.Parse(purl)
+	if err != nil {
+		return "", false
+	}
+
+	qualifiers := u.Query()
+	distro := qualifiers.Get("distro")
+	if distro == "" {
+		return "", false
+	}
+
+	namespace, ok := namespacesByDistro[distro]
+	if !ok {
+		return "", false
+	}
+
+	if strings.HasPrefix(namespace, "amzn:") {
+		if arch := qualifiers.Get("arch"); arch != "" && arch != "x86_64" {
+			namespace = namespace + ":" + arch
+		}
+	}
+
+	return namespace, true
+}
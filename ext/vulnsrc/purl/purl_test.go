@@ -0,0 +1,80 @@
+// Copyright 2019 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package purl
+
+import "testing"
+
+func TestDistroQualifier(t *testing.T) {
+	tests := []struct {
+		namespace string
+		want      string
+		wantOK    bool
+	}{
+		{"amzn:2", "amzn-2", true},
+		{"amzn:2:aarch64", "amzn-2", true},
+		{"debian:11", "debian-11", true},
+		{"unknown:1", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := DistroQualifier(tt.namespace)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("DistroQualifier(%q) = (%q, %v), want (%q, %v)", tt.namespace, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestArchQualifier(t *testing.T) {
+	if arch, ok := ArchQualifier("amzn:2:aarch64"); !ok || arch != "aarch64" {
+		t.Errorf("ArchQualifier(%q) = (%q, %v), want (\"aarch64\", true)", "amzn:2:aarch64", arch, ok)
+	}
+	if arch, ok := ArchQualifier("debian:11"); ok || arch != "" {
+		t.Errorf("ArchQualifier(%q) = (%q, %v), want (\"\", false)", "debian:11", arch, ok)
+	}
+}
+
+func TestNamespaceForPURL(t *testing.T) {
+	tests := []struct {
+		purl   string
+		want   string
+		wantOK bool
+	}{
+		{"pkg:rpm/amzn/openssl@1.1.1?distro=amzn-2", "amzn:2", true},
+		{"pkg:rpm/amzn/openssl@1.1.1?distro=amzn-2&arch=aarch64", "amzn:2:aarch64", true},
+		{"pkg:rpm/amzn/openssl@1.1.1?distro=amzn-2&arch=x86_64", "amzn:2", true},
+		{"pkg:deb/debian/openssl@1.1.1?distro=debian-11", "debian:11", true},
+		{"pkg:deb/debian/openssl@1.1.1", "", false},
+		{"pkg:deb/debian/openssl@1.1.1?distro=not-a-real-distro", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NamespaceForPURL(tt.purl)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NamespaceForPURL(%q) = (%q, %v), want (%q, %v)", tt.purl, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestQualifiers(t *testing.T) {
+	if q, ok := Qualifiers("amzn:2:aarch64"); !ok || q != "arch=aarch64&distro=amzn-2" {
+		t.Errorf("Qualifiers(%q) = (%q, %v), want (\"arch=aarch64&distro=amzn-2\", true)", "amzn:2:aarch64", q, ok)
+	}
+	if q, ok := Qualifiers("debian:11"); !ok || q != "distro=debian-11" {
+		t.Errorf("Qualifiers(%q) = (%q, %v), want (\"distro=debian-11\", true)", "debian:11", q, ok)
+	}
+	if _, ok := Qualifiers("unknown:1"); ok {
+		t.Errorf("Qualifiers(%q) ok = true, want false", "unknown:1")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2017 clair authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httputil implements some http helper functions.
+package httputil
+
+import "net/http"
+
+const userAgent = "clair"
+
+// GetWithUserAgent does a HTTP GET request with a custom clair user agent.
+func GetWithUserAgent(url string) (*http.Response, error) {
+	client := &http.Client{}
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", userAgent)
+
+	return client.Do(request)
+}
+
+// GetConditional does a HTTP GET request with a custom clair user agent and,
+// when etag and/or lastModified are non-empty, the corresponding
+// If-None-Match / If-Modified-Since validators. Callers should check
+// NotModified on the response before reading its body.
+func GetConditional(url, etag, lastModified string) (*http.Response, error) {
+	client := &http.Client{}
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", userAgent)
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		request.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	return client.Do(request)
+}
+
+// Status2xx returns true if the response's status code is in [200, 300).
+func Status2xx(resp *http.Response) bool {
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// NotModified returns true if a GetConditional response indicates that the
+// resource hasn't changed since the validators it was called with.
+func NotModified(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusNotModified
+}